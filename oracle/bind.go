@@ -0,0 +1,199 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// structTag is the struct field tag used to associate a Go struct field
+// with an Oracle bind name or column name.
+const structTag = "oracle"
+
+// NamedArg is a named bind parameter, as an alternative to positional
+// params, for use with ExecuteNamed.
+type NamedArg struct {
+	Name  string
+	Value interface{}
+}
+
+// Named builds a NamedArg, mirroring sql.Named.
+func Named(name string, value interface{}) NamedArg {
+	return NamedArg{Name: name, Value: value}
+}
+
+// ExecuteNamed runs qry binding each NamedArg by name, e.g.
+//
+//	cur.ExecuteNamed(`SELECT :emp_id, :name FROM DUAL`,
+//		Named("emp_id", 42), Named("name", "Scott"))
+func (cur *Cursor) ExecuteNamed(qry string, args ...NamedArg) error {
+	kwargs := make(map[string]interface{}, len(args))
+	for _, a := range args {
+		kwargs[a.Name] = a.Value
+	}
+	return cur.Execute(qry, nil, kwargs)
+}
+
+// BindStruct runs qry binding named parameters taken from src's fields
+// tagged `oracle:"bind_name"`. src must be a struct or a pointer to one.
+func (cur *Cursor) BindStruct(qry string, src interface{}) error {
+	kwargs, err := structToKwargs(src)
+	if err != nil {
+		return err
+	}
+	return cur.Execute(qry, nil, kwargs)
+}
+
+func structToKwargs(src interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("oracle: BindStruct needs a struct, got %T", src)
+	}
+	t := v.Type()
+	kwargs := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported field: Interface() would panic
+		}
+		name := f.Tag.Get(structTag)
+		if name == "" || name == "-" {
+			continue
+		}
+		kwargs[name] = v.Field(i).Interface()
+	}
+	return kwargs, nil
+}
+
+// FetchStruct fetches the next row and assigns its columns into dest's
+// fields, matched by the `oracle:"column_name"` tag (case-insensitively).
+// dest must be a non-nil pointer to a struct. At end of fetch, where
+// FetchOne returns a nil row and no error, FetchStruct returns
+// ErrNoMoreRows.
+func (cur *Cursor) FetchStruct(dest interface{}) error {
+	row, err := cur.FetchOne()
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return ErrNoMoreRows
+	}
+	return assignRow(cur.ColumnNames(), row, dest)
+}
+
+// ErrNoMoreRows is returned by FetchStruct when the cursor is exhausted.
+var ErrNoMoreRows = errors.New("oracle: no more rows")
+
+// FetchAllStruct fetches every remaining row into sliceDest, which must
+// be a pointer to a slice of structs (or of pointers to structs).
+func (cur *Cursor) FetchAllStruct(sliceDest interface{}) error {
+	sv := reflect.ValueOf(sliceDest)
+	if sv.Kind() != reflect.Ptr || sv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("oracle: FetchAllStruct needs a pointer to a slice, got %T", sliceDest)
+	}
+	slice := sv.Elem()
+	elemType := slice.Type().Elem()
+	cols := cur.ColumnNames()
+
+	rows, err := cur.FetchAll()
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		elemPtr := reflect.New(derefType(elemType))
+		if err := assignRow(cols, row, elemPtr.Interface()); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice = reflect.Append(slice, elemPtr)
+		} else {
+			slice = reflect.Append(slice, elemPtr.Elem())
+		}
+	}
+	sv.Elem().Set(slice)
+	return nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func assignRow(cols []string, row []interface{}, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("oracle: fetch destination must be a non-nil pointer, got %T", dest)
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("oracle: fetch destination must point to a struct, got %T", dest)
+	}
+	t := v.Type()
+
+	fieldByColumn := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported field: Set would panic
+		}
+		name := f.Tag.Get(structTag)
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldByColumn[normalizeColumn(name)] = i
+	}
+
+	for i, col := range cols {
+		if i >= len(row) {
+			break
+		}
+		fi, ok := fieldByColumn[normalizeColumn(col)]
+		if !ok {
+			continue
+		}
+		if row[i] == nil {
+			continue
+		}
+		field := v.Field(fi)
+		val := reflect.ValueOf(row[i])
+		if val.Type().AssignableTo(field.Type()) {
+			field.Set(val)
+		} else if val.Type().ConvertibleTo(field.Type()) {
+			field.Set(val.Convert(field.Type()))
+		} else {
+			return fmt.Errorf("oracle: cannot assign column %q (%T) to field %s (%s)",
+				col, row[i], t.Field(fi).Name, field.Type())
+		}
+	}
+	return nil
+}
+
+func normalizeColumn(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c - 'A' + 'a'
+		}
+	}
+	return string(b)
+}
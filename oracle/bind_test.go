@@ -0,0 +1,90 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import "testing"
+
+type employee struct {
+	ID   int64  `oracle:"emp_id"`
+	Name string `oracle:"name"`
+}
+
+func TestStructToKwargs(t *testing.T) {
+	kwargs, err := structToKwargs(employee{ID: 42, Name: "Scott"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kwargs["emp_id"] != int64(42) || kwargs["name"] != "Scott" {
+		t.Fatalf("got %+v", kwargs)
+	}
+}
+
+func TestAssignRow(t *testing.T) {
+	var e employee
+	if err := assignRow([]string{"EMP_ID", "NAME"}, []interface{}{int64(7), "Miller"}, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 7 || e.Name != "Miller" {
+		t.Fatalf("got %+v", e)
+	}
+}
+
+type employeeWithUnexported struct {
+	ID     int64  `oracle:"emp_id"`
+	Name   string `oracle:"name"`
+	secret int64  `oracle:"secret"` // unexported: must be skipped, not panic
+}
+
+func TestStructToKwargsSkipsUnexportedFields(t *testing.T) {
+	kwargs, err := structToKwargs(employeeWithUnexported{ID: 1, Name: "x", secret: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := kwargs["secret"]; ok {
+		t.Fatalf("got %+v, want unexported field skipped", kwargs)
+	}
+}
+
+func TestAssignRowSkipsUnexportedFields(t *testing.T) {
+	var e employeeWithUnexported
+	if err := assignRow([]string{"SECRET"}, []interface{}{int64(9)}, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.secret != 0 {
+		t.Fatalf("got %+v, want unexported field left untouched", e)
+	}
+}
+
+func TestFetchCursor(t *testing.T) {
+	conn := getConnection(t)
+	if !conn.IsConnected() {
+		t.Skip("cannot test without dsn!")
+	}
+	cur := conn.NewCursor()
+	defer cur.Close()
+
+	if err := cur.ExecuteNamed(`SELECT :emp_id emp_id, :name name FROM DUAL`,
+		Named("emp_id", 42), Named("name", "Scott")); err != nil {
+		t.Fatal(err)
+	}
+	var e employee
+	if err := cur.FetchStruct(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 42 || e.Name != "Scott" {
+		t.Fatalf("got %+v", e)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExecuteContext is like Execute, but interrupts the in-flight OCI call
+// via OCIBreak/OCIReset as soon as ctx is done, instead of blocking the
+// calling goroutine until the server responds.
+func (cur *Cursor) ExecuteContext(ctx context.Context, qry string, params []interface{}, kwargs map[string]interface{}) error {
+	return cur.runInterruptibly(ctx, func() error {
+		return cur.Execute(qry, params, kwargs)
+	})
+}
+
+// FetchContext is like FetchOne, but is interruptible the same way
+// ExecuteContext is.
+func (cur *Cursor) FetchContext(ctx context.Context) (row []interface{}, err error) {
+	err = cur.runInterruptibly(ctx, func() error {
+		var fetchErr error
+		row, fetchErr = cur.FetchOne()
+		return fetchErr
+	})
+	return row, err
+}
+
+// runInterruptibly runs fn (on a separate goroutine, unless ctx can never
+// be done) and, if ctx is done before fn returns, calls OCIBreak (via the Cursor's connection) to
+// unblock the underlying OCI call, followed by OCIReset to clear the
+// resulting "operation cancelled" error off the session so the
+// connection is valid for the next call again. If the reset itself
+// fails, the connection is left unusable and runInterruptibly reports
+// that via the returned error instead of silently pretending it is
+// fine; callers that got the Connection from a Pool are already
+// protected, since Pool.Acquire re-Pings an idle connection before
+// handing it out and discards it on failure.
+func (cur *Cursor) runInterruptibly(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		cur.conn.Break()
+		<-done // wait for fn to actually unwind before returning
+		if err := cur.conn.Reset(); err != nil {
+			return fmt.Errorf("oracle: %w (connection left unusable, reset failed: %s)", ctx.Err(), err)
+		}
+		return ctx.Err()
+	}
+}
+
+// SetCallTimeout sets Oracle's per-call timeout (OCI_ATTR_CALL_TIMEOUT,
+// available since Oracle client 18c) on the connection, bounding every
+// subsequent OCI round trip to d. A zero d disables the timeout.
+func (conn Connection) SetCallTimeout(d time.Duration) error {
+	return conn.setCallTimeoutMillis(int(d / time.Millisecond))
+}
+
+// SetStmtCacheSize sets the number of statements OCI keeps cached per
+// session (OCI_ATTR_STMTCACHESIZE on the service context), so repeated
+// Prepare calls for the same SQL text can reuse a server-side cursor
+// instead of reparsing it every time.
+func (conn Connection) SetStmtCacheSize(n int) error {
+	return conn.setStmtCacheSize(n)
+}
+
+// ctxTimeoutSeconds turns ctx's deadline (if any) into a whole-second
+// timeout suitable for Connect, or returns ctx.Err() if ctx is already
+// done.
+func ctxTimeoutSeconds(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	timeout := 0
+	if deadline, ok := ctx.Deadline(); ok {
+		if d := time.Until(deadline); d > 0 {
+			timeout = int(d / time.Second)
+			if timeout == 0 {
+				timeout = 1 // round up: a sub-second deadline still means "soon", not "unbounded"
+			}
+		}
+	}
+	return timeout, nil
+}
+
+// ConnectContext is like Connect, but derives the connect timeout from
+// ctx's deadline (if any) instead of taking it as an explicit argument.
+func (conn Connection) ConnectContext(ctx context.Context, autocommit bool) error {
+	timeout, err := ctxTimeoutSeconds(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.Connect(timeout, autocommit)
+}
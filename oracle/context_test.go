@@ -0,0 +1,40 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExecuteContextDeadlineExceeded(t *testing.T) {
+	conn := getConnection(t)
+	if !conn.IsConnected() {
+		t.Skip("cannot test without dsn!")
+	}
+	cur := conn.NewCursor()
+	defer cur.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	qry := `BEGIN DBMS_LOCK.SLEEP(5); END;`
+	err := cur.ExecuteContext(ctx, qry, nil, nil)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
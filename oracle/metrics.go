@@ -0,0 +1,180 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the histogram bucket upper bounds (seconds)
+// used by PromMetrics, modeled on Prometheus's own client library
+// defaults.
+var defaultLatencyBuckets = []float64{
+	.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10,
+}
+
+// PromMetrics is a built-in Tracer that keeps Prometheus-style counters
+// and latency histograms in memory and can render them in the
+// Prometheus text exposition format via WriteTo, without depending on
+// the prometheus client library.
+type PromMetrics struct {
+	mu sync.Mutex
+
+	connects        uint64
+	connectErrors   uint64
+	reconnects      uint64
+	reconnectErrors uint64
+	executes        uint64
+	executeErrors   uint64
+	rowsFetched     uint64
+	closes          uint64
+	connectLatency  histogram
+	executeLatency  histogram
+	poolWait        histogram
+}
+
+// NewPromMetrics returns a ready-to-use PromMetrics.
+func NewPromMetrics() *PromMetrics {
+	return &PromMetrics{
+		connectLatency: newHistogram(defaultLatencyBuckets),
+		executeLatency: newHistogram(defaultLatencyBuckets),
+		poolWait:       newHistogram(defaultLatencyBuckets),
+	}
+}
+
+var _ Tracer = (*PromMetrics)(nil)
+
+func (m *PromMetrics) OnConnect(dsn string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connects++
+	if err != nil {
+		m.connectErrors++
+	}
+	m.connectLatency.observe(d.Seconds())
+}
+
+func (m *PromMetrics) OnExecute(qry string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executes++
+	if err != nil {
+		m.executeErrors++
+	}
+	m.executeLatency.observe(d.Seconds())
+}
+
+func (m *PromMetrics) OnFetch(rows int, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rowsFetched += uint64(rows)
+}
+
+func (m *PromMetrics) OnClose(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closes++
+}
+
+// OnPoolWait implements Tracer, recording how long a Pool.Acquire call
+// waited for a connection to become available.
+func (m *PromMetrics) OnPoolWait(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.poolWait.observe(d.Seconds())
+}
+
+// OnReconnect implements Tracer, counting dials a Pool made to replace
+// a connection that failed its pre-use Ping, separately from ordinary
+// dials counted by OnConnect.
+func (m *PromMetrics) OnReconnect(dsn string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reconnects++
+	if err != nil {
+		m.reconnectErrors++
+	}
+}
+
+// WriteTo renders the current metrics in the Prometheus text exposition
+// format.
+func (m *PromMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	write := func(format string, args ...interface{}) {
+		written, _ := fmt.Fprintf(w, format, args...)
+		n += int64(written)
+	}
+
+	write("# TYPE goracle_connects_total counter\ngoracle_connects_total %d\n", m.connects)
+	write("# TYPE goracle_connect_errors_total counter\ngoracle_connect_errors_total %d\n", m.connectErrors)
+	write("# TYPE goracle_reconnects_total counter\ngoracle_reconnects_total %d\n", m.reconnects)
+	write("# TYPE goracle_reconnect_errors_total counter\ngoracle_reconnect_errors_total %d\n", m.reconnectErrors)
+	write("# TYPE goracle_executes_total counter\ngoracle_executes_total %d\n", m.executes)
+	write("# TYPE goracle_execute_errors_total counter\ngoracle_execute_errors_total %d\n", m.executeErrors)
+	write("# TYPE goracle_rows_fetched_total counter\ngoracle_rows_fetched_total %d\n", m.rowsFetched)
+	write("# TYPE goracle_closes_total counter\ngoracle_closes_total %d\n", m.closes)
+	m.connectLatency.writeTo(w, &n, "goracle_connect_latency_seconds")
+	m.executeLatency.writeTo(w, &n, "goracle_execute_latency_seconds")
+	m.poolWait.writeTo(w, &n, "goracle_pool_wait_seconds")
+	return n, nil
+}
+
+// histogram is a minimal cumulative-bucket histogram, enough to render
+// Prometheus's "le" bucket format without pulling in the client library.
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) histogram {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return histogram{buckets: b, counts: make([]uint64, len(b))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.sum += v
+	h.count++
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *histogram) writeTo(w io.Writer, n *int64, name string) {
+	written, _ := fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	*n += int64(written)
+	for i, bound := range h.buckets {
+		written, _ = fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, bound, h.counts[i])
+		*n += int64(written)
+	}
+	written, _ = fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	*n += int64(written)
+	written, _ = fmt.Fprintf(w, "%s_sum %g\n", name, h.sum)
+	*n += int64(written)
+	written, _ = fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+	*n += int64(written)
+}
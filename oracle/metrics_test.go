@@ -0,0 +1,50 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromMetrics(t *testing.T) {
+	m := NewPromMetrics()
+	m.OnConnect("orcl", 10*time.Millisecond, nil)
+	m.OnConnect("orcl", 10*time.Millisecond, errors.New("boom"))
+	m.OnExecute("SELECT 1 FROM DUAL", 5*time.Millisecond, nil)
+	m.OnFetch(3, time.Millisecond, nil)
+	m.OnClose(nil)
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"goracle_connects_total 2",
+		"goracle_connect_errors_total 1",
+		"goracle_executes_total 1",
+		"goracle_rows_fetched_total 3",
+		"goracle_closes_total 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by Acquire once the Pool has been closed.
+var ErrPoolClosed = errors.New("oracle: pool is closed")
+
+// PoolOptions configures a Pool. Zero values mean "no limit" for the
+// Max* fields, except MaxOpen, which defaults to 10 when zero, and
+// MaxIdle, which defaults to MaxOpen when zero.
+type PoolOptions struct {
+	User, Password, SID string
+	Autocommit          bool
+
+	MaxOpen     int           // maximum number of open connections; 0 means 10
+	MaxIdle     int           // maximum number of idle connections kept around; 0 means MaxOpen
+	MaxLifetime time.Duration // connections older than this are closed on Release; 0 means no limit
+	MaxIdleTime time.Duration // idle connections older than this are evicted; 0 means no limit
+
+	// EvictInterval controls how often the background evictor wakes up
+	// to close expired idle connections. 0 means 1 minute.
+	EvictInterval time.Duration
+
+	// CallTimeout, if set, is applied to every dialed connection via
+	// Connection.SetCallTimeout, bounding each subsequent OCI round
+	// trip made through it.
+	CallTimeout time.Duration
+
+	// Tracer, if set, receives OnConnect/OnPoolWait/OnReconnect events
+	// from the Pool itself, and OnExecute/OnFetch/OnClose events from
+	// every Connection/Cursor obtained via Acquire, exactly as if each
+	// had been wrapped in NewTracedConnection individually.
+	Tracer Tracer
+}
+
+// Pool manages a bounded set of Connections, handing them out via
+// Acquire and taking them back via PooledConn.Release.
+type Pool struct {
+	opts PoolOptions
+
+	mu      sync.Mutex
+	idle    []*pooledConn
+	inUse   int
+	waiters []chan struct{}
+	closed  bool
+
+	stopEvict chan struct{}
+}
+
+// noopTracer discards every event. It backs Pool.tracer when
+// PoolOptions.Tracer is nil, so Pool can always route its connections
+// through TracedConnection instead of branching on whether a Tracer was
+// configured.
+type noopTracer struct{}
+
+func (noopTracer) OnConnect(string, time.Duration, error) {}
+func (noopTracer) OnExecute(string, time.Duration, error) {}
+func (noopTracer) OnFetch(int, time.Duration, error)      {}
+func (noopTracer) OnClose(error)                          {}
+func (noopTracer) OnPoolWait(time.Duration)               {}
+func (noopTracer) OnReconnect(string, error)              {}
+
+var _ Tracer = noopTracer{}
+
+// tracer returns the configured Tracer, or a no-op one if none was set.
+func (p *Pool) tracer() Tracer {
+	if p.opts.Tracer != nil {
+		return p.opts.Tracer
+	}
+	return noopTracer{}
+}
+
+type pooledConn struct {
+	conn       TracedConnection
+	createdAt  time.Time
+	returnedAt time.Time
+}
+
+// PooledConn is a Connection on loan from a Pool. Callers must call
+// Release exactly once when done with it. Its Execute/Fetch calls
+// report to the Pool's Tracer just like a connection obtained directly
+// from NewTracedConnection.
+type PooledConn struct {
+	TracedConnection
+	pool *Pool
+	pc   *pooledConn
+}
+
+// NewPool creates a Pool with the given options. It does not open any
+// connections eagerly; the first Acquire creates one.
+func NewPool(opts PoolOptions) *Pool {
+	if opts.MaxOpen <= 0 {
+		opts.MaxOpen = 10
+	}
+	if opts.MaxIdle <= 0 {
+		opts.MaxIdle = opts.MaxOpen
+	}
+	if opts.EvictInterval <= 0 {
+		opts.EvictInterval = time.Minute
+	}
+	p := &Pool{opts: opts, stopEvict: make(chan struct{})}
+	go p.evictLoop()
+	return p
+}
+
+// Acquire returns a healthy Connection (an idle one is Pinged first, a
+// new one is freshly connected), waiting for one to become available if
+// the pool is at MaxOpen, or until ctx is done.
+func (p *Pool) Acquire(ctx context.Context) (*PooledConn, error) {
+	reconnect := false // set once a popped idle connection fails Ping, so the next dial is reported as a reconnect
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		if pc := p.popIdleLocked(); pc != nil {
+			p.inUse++
+			p.mu.Unlock()
+			if err := pc.conn.Ping(); err != nil {
+				pc.conn.Close()
+				p.mu.Lock()
+				p.inUse--
+				p.broadcastLocked()
+				p.mu.Unlock()
+				reconnect = true
+				continue
+			}
+			return &PooledConn{TracedConnection: pc.conn, pool: p, pc: pc}, nil
+		}
+		if p.inUse < p.opts.MaxOpen {
+			p.inUse++
+			p.mu.Unlock()
+			conn, err := p.dial(ctx, reconnect)
+			reconnect = false
+			if err != nil {
+				p.mu.Lock()
+				p.inUse--
+				p.broadcastLocked()
+				p.mu.Unlock()
+				return nil, err
+			}
+			pc := &pooledConn{conn: conn, createdAt: time.Now()}
+			return &PooledConn{TracedConnection: conn, pool: p, pc: pc}, nil
+		}
+
+		// The pool is at MaxOpen: wait for Release/Close to free a slot,
+		// or for ctx to end. waitCh is closed by whichever goroutine
+		// frees a slot, under p.mu, so there is no race between a
+		// waiter registering itself and a signal being sent.
+		waitStart := time.Now()
+		waitCh := make(chan struct{})
+		p.waiters = append(p.waiters, waitCh)
+		p.mu.Unlock()
+
+		select {
+		case <-waitCh:
+			p.tracer().OnPoolWait(time.Since(waitStart))
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.removeWaiterLocked(waitCh)
+			p.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// broadcastLocked wakes every goroutine currently waiting in Acquire so
+// each can recheck whether a slot is now free. Must be called with
+// p.mu held.
+func (p *Pool) broadcastLocked() {
+	for _, ch := range p.waiters {
+		close(ch)
+	}
+	p.waiters = nil
+}
+
+// removeWaiterLocked drops ch from p.waiters without closing it, used
+// when a waiter gives up due to ctx being done. Must be called with
+// p.mu held.
+func (p *Pool) removeWaiterLocked(ch chan struct{}) {
+	for i, w := range p.waiters {
+		if w == ch {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// dial opens a new connection, wrapping it in a TracedConnection so
+// every Execute/Fetch made through it (and not just the dial itself)
+// reports to the Pool's Tracer. It honors ctx's deadline for the
+// connect itself (Acquire's own select only bounds how long it waits
+// for a free slot, not the dial), and applies CallTimeout if
+// configured. If reconnect is true, this dial is replacing a
+// connection that failed its pre-use Ping, and is additionally reported
+// via Tracer.OnReconnect.
+func (p *Pool) dial(ctx context.Context, reconnect bool) (TracedConnection, error) {
+	tracer := p.tracer()
+	start := time.Now()
+	conn, err := NewConnection(p.opts.User, p.opts.Password, p.opts.SID, p.opts.Autocommit)
+	if err != nil {
+		tracer.OnConnect(p.opts.SID, time.Since(start), err)
+		if reconnect {
+			tracer.OnReconnect(p.opts.SID, err)
+		}
+		return TracedConnection{}, err
+	}
+
+	tc := NewTracedConnection(conn, p.opts.SID, tracer)
+	err = tc.ConnectContext(ctx, p.opts.Autocommit)
+	if err == nil && p.opts.CallTimeout > 0 {
+		err = tc.SetCallTimeout(p.opts.CallTimeout)
+	}
+	if reconnect {
+		tracer.OnReconnect(p.opts.SID, err)
+	}
+	if err != nil {
+		return TracedConnection{}, err
+	}
+	return tc, nil
+}
+
+// popIdleLocked must be called with p.mu held. It returns the
+// most-recently-returned idle connection that hasn't exceeded
+// MaxLifetime, closing and discarding any expired ones along the way.
+func (p *Pool) popIdleLocked() *pooledConn {
+	for len(p.idle) > 0 {
+		n := len(p.idle) - 1
+		pc := p.idle[n]
+		p.idle = p.idle[:n]
+		if p.opts.MaxLifetime > 0 && time.Since(pc.createdAt) > p.opts.MaxLifetime {
+			pc.conn.Close()
+			continue
+		}
+		return pc
+	}
+	return nil
+}
+
+// Release returns the connection to the pool, or closes it outright if
+// it has exceeded MaxLifetime or the pool already has MaxIdle idle
+// connections.
+func (c *PooledConn) Release() {
+	p := c.pool
+	// Ping before taking p.mu: a connection left broken by, e.g., a
+	// canceled ExecuteContext (see Cursor.ExecuteContext) must not be
+	// handed back out as idle.
+	healthy := c.Ping() == nil
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse--
+	c.pc.returnedAt = time.Now()
+
+	expired := p.opts.MaxLifetime > 0 && time.Since(c.pc.createdAt) > p.opts.MaxLifetime
+	if !healthy || p.closed || expired || len(p.idle) >= p.opts.MaxIdle {
+		c.Close()
+	} else {
+		p.idle = append(p.idle, c.pc)
+	}
+	p.broadcastLocked()
+}
+
+// Stats is a point-in-time snapshot of Pool usage.
+type Stats struct {
+	InUse   int
+	Idle    int
+	Waiting int // goroutines currently blocked in Acquire waiting for a connection
+	// MaxOpen is the configured upper bound on open connections.
+	MaxOpen int
+}
+
+// Stats returns a snapshot of the pool's current usage.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{InUse: p.inUse, Idle: len(p.idle), Waiting: len(p.waiters), MaxOpen: p.opts.MaxOpen}
+}
+
+// Close stops the background evictor and closes every idle connection.
+// Connections currently on loan are closed as they are Released.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	close(p.stopEvict)
+	for _, pc := range idle {
+		pc.conn.Close()
+	}
+	p.mu.Lock()
+	p.broadcastLocked()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Pool) evictLoop() {
+	t := time.NewTicker(p.opts.EvictInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stopEvict:
+			return
+		case <-t.C:
+			p.evictExpiredIdle()
+		}
+	}
+}
+
+func (p *Pool) evictExpiredIdle() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.opts.MaxIdleTime <= 0 && p.opts.MaxLifetime <= 0 {
+		return
+	}
+	kept := p.idle[:0]
+	for _, pc := range p.idle {
+		tooOld := p.opts.MaxLifetime > 0 && time.Since(pc.createdAt) > p.opts.MaxLifetime
+		tooIdle := p.opts.MaxIdleTime > 0 && time.Since(pc.returnedAt) > p.opts.MaxIdleTime
+		if tooOld || tooIdle {
+			pc.conn.Close()
+			continue
+		}
+		kept = append(kept, pc)
+	}
+	p.idle = kept
+}
@@ -0,0 +1,139 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// spyTracer counts OnReconnect calls, without caring about the rest of
+// the Tracer interface.
+type spyTracer struct {
+	reconnects int
+}
+
+func (s *spyTracer) OnConnect(string, time.Duration, error) {}
+func (s *spyTracer) OnExecute(string, time.Duration, error) {}
+func (s *spyTracer) OnFetch(int, time.Duration, error)      {}
+func (s *spyTracer) OnClose(error)                          {}
+func (s *spyTracer) OnPoolWait(time.Duration)               {}
+func (s *spyTracer) OnReconnect(string, error)              { s.reconnects++ }
+
+// TestPoolAcquireReportsReconnect checks that Acquire tells the Tracer
+// apart a dial that replaces a connection which failed its pre-use Ping
+// from an ordinary first dial.
+func TestPoolAcquireReportsReconnect(t *testing.T) {
+	tracer := &spyTracer{}
+	p := NewPool(PoolOptions{MaxOpen: 1, Tracer: tracer})
+	defer p.Close()
+
+	p.mu.Lock()
+	p.idle = append(p.idle, &pooledConn{conn: NewTracedConnection(Connection{}, "", tracer)})
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	p.Acquire(ctx) // the idle conn's Ping fails (it's a zero Connection), the redial may too without a dsn; only OnReconnect matters here
+
+	if tracer.reconnects != 1 {
+		t.Fatalf("got %d reconnects, want 1", tracer.reconnects)
+	}
+}
+
+func TestPoolAcquireRelease(t *testing.T) {
+	if *dsn == "" {
+		t.Skip("cannot test pool without dsn!")
+	}
+	user, passw, sid := SplitDSN(*dsn)
+	p := NewPool(PoolOptions{User: user, Password: passw, SID: sid, MaxOpen: 2})
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c1, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire: %s", err)
+	}
+	if st := p.Stats(); st.InUse != 1 {
+		t.Fatalf("stats = %+v, want InUse=1", st)
+	}
+	c1.Release()
+	if st := p.Stats(); st.InUse != 0 || st.Idle != 1 {
+		t.Fatalf("stats = %+v, want InUse=0 Idle=1", st)
+	}
+
+	c2, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("re-acquire: %s", err)
+	}
+	c2.Release()
+}
+
+// TestPoolDialHonorsCanceledContext checks that a context already done
+// before Acquire ever reaches dial is honored there, not just in the
+// wait-for-a-free-slot select.
+func TestPoolDialHonorsCanceledContext(t *testing.T) {
+	if *dsn == "" {
+		t.Skip("cannot test pool without dsn!")
+	}
+	user, passw, sid := SplitDSN(*dsn)
+	p := NewPool(PoolOptions{User: user, Password: passw, SID: sid, MaxOpen: 1})
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Acquire(ctx); err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
+
+// TestPoolAcquireWaitsWithoutCrashing exercises the path where Acquire
+// has to block because the pool is already at MaxOpen. It used to crash
+// the whole process with "fatal error: sync: unlock of unlocked mutex"
+// regardless of dsn, since the bug was in the locking itself, not in
+// anything that talks to Oracle.
+func TestPoolAcquireWaitsWithoutCrashing(t *testing.T) {
+	p := NewPool(PoolOptions{MaxOpen: 1})
+	defer p.Close()
+
+	p.mu.Lock()
+	p.inUse = 1 // simulate a connection already checked out, saturating the pool
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := p.Acquire(ctx); err != context.DeadlineExceeded {
+			t.Errorf("got %v, want context.DeadlineExceeded", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Acquire did not return after ctx deadline")
+	}
+
+	if st := p.Stats(); st.Waiting != 0 {
+		t.Fatalf("stats = %+v, want Waiting=0 after ctx gave up", st)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer receives instrumentation events for Connection/Cursor/Pool
+// activity. Plug in an OpenTelemetry span recorder, PromMetrics, or any
+// other observability backend. This is additive, app-level
+// instrumentation: it does not replace the package-level CTrace/IsDebug
+// flags, which still control low-level OCI call tracing independently.
+type Tracer interface {
+	// OnConnect is called after a Connect attempt, successful or not.
+	OnConnect(dsn string, d time.Duration, err error)
+	// OnExecute is called after a Cursor.Execute, successful or not.
+	OnExecute(qry string, d time.Duration, err error)
+	// OnFetch is called after a Cursor Fetch* call, reporting the
+	// number of rows it returned (0 on error or end of fetch).
+	OnFetch(rows int, d time.Duration, err error)
+	// OnClose is called after a Connection is closed.
+	OnClose(err error)
+	// OnPoolWait is called after a Pool.Acquire that had to wait for a
+	// connection to free up, reporting how long it waited.
+	OnPoolWait(d time.Duration)
+	// OnReconnect is called after a Pool dials a replacement connection
+	// for one that failed its pre-use Ping, reporting whether the
+	// reconnect itself succeeded.
+	OnReconnect(dsn string, err error)
+}
+
+// TracedConnection wraps a Connection, reporting every Connect/Close and
+// the Cursor Execute/Fetch calls that TracedCursor reports to a Tracer.
+type TracedConnection struct {
+	Connection
+	dsn    string
+	tracer Tracer
+}
+
+// NewTracedConnection wraps conn so its activity is reported to tracer.
+// dsn is passed through to Tracer.OnConnect purely for labeling.
+func NewTracedConnection(conn Connection, dsn string, tracer Tracer) TracedConnection {
+	return TracedConnection{Connection: conn, dsn: dsn, tracer: tracer}
+}
+
+// Connect connects the underlying Connection and reports the attempt.
+func (tc TracedConnection) Connect(timeout int, autocommit bool) error {
+	start := time.Now()
+	err := tc.Connection.Connect(timeout, autocommit)
+	tc.tracer.OnConnect(tc.dsn, time.Since(start), err)
+	return err
+}
+
+// ConnectContext is like Connect, but derives the connect timeout from
+// ctx the same way Connection.ConnectContext does, reporting the
+// attempt through tc's Tracer exactly like Connect does.
+func (tc TracedConnection) ConnectContext(ctx context.Context, autocommit bool) error {
+	timeout, err := ctxTimeoutSeconds(ctx)
+	if err != nil {
+		return err
+	}
+	return tc.Connect(timeout, autocommit)
+}
+
+// Close closes the underlying Connection and reports it.
+func (tc TracedConnection) Close() error {
+	err := tc.Connection.Close()
+	tc.tracer.OnClose(err)
+	return err
+}
+
+// NewCursor returns a Cursor that reports its Execute/Fetch calls to the
+// same Tracer as tc.
+func (tc TracedConnection) NewCursor() TracedCursor {
+	return TracedCursor{Cursor: tc.Connection.NewCursor(), tracer: tc.tracer}
+}
+
+// TracedCursor wraps a Cursor, reporting Execute/Fetch calls to a Tracer.
+// Only Execute, FetchOne, FetchMany and FetchAll are reported: the other
+// methods promoted from Cursor, such as ExecuteContext, FetchContext,
+// ExecuteNamed, BindStruct, FetchStruct and FetchAllStruct, run on the
+// wrapped Cursor directly and are not reported.
+type TracedCursor struct {
+	Cursor
+	tracer Tracer
+}
+
+func (tcur TracedCursor) Execute(qry string, params []interface{}, kwargs map[string]interface{}) error {
+	start := time.Now()
+	err := tcur.Cursor.Execute(qry, params, kwargs)
+	tcur.tracer.OnExecute(qry, time.Since(start), err)
+	return err
+}
+
+func (tcur TracedCursor) FetchOne() ([]interface{}, error) {
+	start := time.Now()
+	row, err := tcur.Cursor.FetchOne()
+	n := 0
+	if row != nil {
+		n = 1
+	}
+	tcur.tracer.OnFetch(n, time.Since(start), err)
+	return row, err
+}
+
+func (tcur TracedCursor) FetchMany(n int) ([][]interface{}, error) {
+	start := time.Now()
+	rows, err := tcur.Cursor.FetchMany(n)
+	tcur.tracer.OnFetch(len(rows), time.Since(start), err)
+	return rows, err
+}
+
+func (tcur TracedCursor) FetchAll() ([][]interface{}, error) {
+	start := time.Now()
+	rows, err := tcur.Cursor.FetchAll()
+	tcur.tracer.OnFetch(len(rows), time.Since(start), err)
+	return rows, err
+}
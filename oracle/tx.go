@@ -0,0 +1,168 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Isolation is the transaction isolation level, as supported by Oracle's
+// SET TRANSACTION ISOLATION LEVEL clause.
+type Isolation int
+
+const (
+	// ReadCommitted is Oracle's default isolation level.
+	ReadCommitted Isolation = iota
+	// Serializable requests Oracle's SERIALIZABLE isolation level.
+	Serializable
+)
+
+func (i Isolation) String() string {
+	if i == Serializable {
+		return "SERIALIZABLE"
+	}
+	return "READ COMMITTED"
+}
+
+// TxOptions configures a transaction started with Connection.Begin.
+type TxOptions struct {
+	ReadOnly  bool
+	Isolation Isolation
+}
+
+// ErrTxDone is returned by Tx methods once the transaction has already
+// been committed or rolled back.
+var ErrTxDone = errors.New("oracle: transaction has already been committed or rolled back")
+
+// Tx represents an in-progress Oracle transaction, started by
+// Connection.Begin. Oracle has no explicit BEGIN statement; Begin
+// instead suppresses autocommit on the underlying Connection and, for
+// TxOptions{ReadOnly: true} or a non-default Isolation, issues a
+// SET TRANSACTION statement as Oracle requires that to be the first
+// statement of the transaction.
+type Tx struct {
+	conn Connection
+	done bool
+}
+
+// Begin starts a transaction on conn according to opts (nil for
+// defaults: read/write, READ COMMITTED), suppressing autocommit so
+// executed statements stay pending until Commit or Rollback.
+func (conn Connection) Begin(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !conn.IsConnected() {
+		return nil, errors.New("oracle: cannot begin a transaction on a closed connection")
+	}
+
+	var stmt string
+	if opts != nil {
+		stmt = setTransactionStmt(*opts)
+	}
+	if stmt != "" {
+		// SET TRANSACTION must be the first statement of the transaction,
+		// so run it before touching autocommit: if it fails, the
+		// connection is left exactly as Begin found it.
+		cur := conn.NewCursor()
+		err := cur.Execute(stmt, nil, nil)
+		cur.Close()
+		if err != nil {
+			return nil, fmt.Errorf("oracle: %s: %w", stmt, err)
+		}
+	}
+	conn.SetAutoCommit(false)
+	return &Tx{conn: conn}, nil
+}
+
+func setTransactionStmt(opts TxOptions) string {
+	switch {
+	case opts.ReadOnly:
+		return "SET TRANSACTION READ ONLY"
+	case opts.Isolation == Serializable:
+		return "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE"
+	default:
+		return ""
+	}
+}
+
+// NewCursor returns a Cursor pinned to tx: since conn's autocommit was
+// suppressed by Begin, every Execute on it stays pending until the
+// transaction is committed or rolled back.
+func (tx *Tx) NewCursor() Cursor {
+	return tx.conn.NewCursor()
+}
+
+// Commit commits the transaction and then turns autocommit on for the
+// connection; the autocommit setting it had before Begin is not restored.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	defer tx.conn.SetAutoCommit(true)
+	return tx.conn.Commit()
+}
+
+// Rollback rolls back the entire transaction and then turns autocommit
+// on for the connection; the autocommit setting it had before Begin is
+// not restored.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	defer tx.conn.SetAutoCommit(true)
+	return tx.conn.Rollback()
+}
+
+// Savepoint marks the current point in the transaction as name, so a
+// later RollbackTo(name) undoes only the work done since.
+func (tx *Tx) Savepoint(name string) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return tx.exec("SAVEPOINT " + name)
+}
+
+// RollbackTo rolls back to the savepoint previously marked via
+// Savepoint(name), without ending the transaction.
+func (tx *Tx) RollbackTo(name string) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return tx.exec("ROLLBACK TO SAVEPOINT " + name)
+}
+
+// Release rolls nothing back and sends nothing to the server. Oracle
+// has no RELEASE SAVEPOINT statement, so name stays a valid RollbackTo
+// target until the transaction ends; Release only reports ErrTxDone
+// once the transaction is over. It exists for API parity with databases
+// that do support releasing.
+func (tx *Tx) Release(name string) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	return nil
+}
+
+func (tx *Tx) exec(stmt string) error {
+	cur := tx.conn.NewCursor()
+	defer cur.Close()
+	return cur.Execute(stmt, nil, nil)
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright 2013 Tamás Gulácsi
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package oracle
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetTransactionStmt(t *testing.T) {
+	if got := setTransactionStmt(TxOptions{}); got != "" {
+		t.Fatalf("got %q for default options", got)
+	}
+	if got := setTransactionStmt(TxOptions{ReadOnly: true}); got != "SET TRANSACTION READ ONLY" {
+		t.Fatalf("got %q", got)
+	}
+	if got := setTransactionStmt(TxOptions{Isolation: Serializable}); got != "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestTxSavepoint(t *testing.T) {
+	conn := getConnection(t)
+	if !conn.IsConnected() {
+		t.Skip("cannot test without dsn!")
+	}
+	tx, err := conn.Begin(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Savepoint("before_insert"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.RollbackTo("before_insert"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != ErrTxDone {
+		t.Fatalf("got %v, want ErrTxDone", err)
+	}
+}